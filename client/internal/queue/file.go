@@ -0,0 +1,485 @@
+package queue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+)
+
+// Record kinds stored in the append-only log.
+const (
+	recordPut byte = 1
+	recordAck byte = 2
+)
+
+// defaultCompactInterval is used when NewFileQueue is given a zero interval.
+const defaultCompactInterval = time.Minute
+
+// deadRatioToCompact triggers an extra, immediate compaction once the log
+// accumulates this many tombstoned (acked) entries ahead of live ones, so a
+// bursty workload doesn't have to wait for the periodic tick to reclaim
+// space.
+const deadRatioToCompact = 1000
+
+// FileQueue is a file-backed types.OutboundQueue. It stores messages in an
+// append-only log using length-prefixed, CRC-checked framing so that a crash
+// mid-write leaves the log readable up to the last complete record, and
+// periodically compacts the log to drop acked entries and bound its size on
+// disk.
+type FileQueue struct {
+	mu      sync.Mutex
+	notFull *sync.Cond
+	path    string
+	file    *os.File
+
+	capacity   int
+	policy     types.QueueOverflowPolicy
+	onOverflow func(dropped *types.QueuedMessage)
+
+	// order is the FIFO sequence of ids ever put into the log that have not
+	// yet been compacted away; live holds the ones that are not yet acked.
+	// Acked ids are left in order as tombstones until the next compaction.
+	order  []uint64
+	live   map[uint64]*types.QueuedMessage
+	dead   int
+	nextId uint64
+
+	closeOnce sync.Once
+	stopCh    chan struct{}
+	stopped   chan struct{}
+}
+
+// NewFileQueue opens (or creates) the log file at path, replays it to
+// recover any un-acked messages left over from a previous process, and
+// starts a background goroutine that compacts the log every compactInterval.
+// A zero compactInterval uses a default of one minute.
+func NewFileQueue(
+	path string,
+	capacity int,
+	policy types.QueueOverflowPolicy,
+	onOverflow func(dropped *types.QueuedMessage),
+	compactInterval time.Duration,
+) (*FileQueue, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open outbound queue file %s: %w", path, err)
+	}
+
+	q := &FileQueue{
+		path:       path,
+		file:       f,
+		capacity:   capacity,
+		policy:     policy,
+		onOverflow: onOverflow,
+		live:       map[uint64]*types.QueuedMessage{},
+		stopCh:     make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	q.notFull = sync.NewCond(&q.mu)
+
+	if err := q.replay(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if compactInterval <= 0 {
+		compactInterval = defaultCompactInterval
+	}
+	go q.compactLoop(compactInterval)
+
+	return q, nil
+}
+
+// Close stops the background compaction goroutine and closes the log file.
+// It does not delete buffered, un-acked messages: they will be recovered the
+// next time NewFileQueue is called with the same path.
+func (q *FileQueue) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.stopCh)
+		<-q.stopped
+	})
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+func (q *FileQueue) compactLoop(interval time.Duration) {
+	defer close(q.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.stopCh:
+			return
+		case <-ticker.C:
+			q.mu.Lock()
+			err := q.compactLocked()
+			q.mu.Unlock()
+			// Compaction is best-effort; a failure just means the log keeps
+			// growing until the next successful attempt.
+			_ = err
+		}
+	}
+}
+
+func (q *FileQueue) Enqueue(msg *types.QueuedMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.capacity > 0 && len(q.live) >= q.capacity {
+		switch q.policy {
+		case types.QueueDropOldest:
+			oldest, ok := q.oldestLiveLocked()
+			if !ok {
+				break
+			}
+			if err := q.writeRecordLocked(recordAck, encodeAck([]uint64{oldest.Id})); err != nil {
+				return err
+			}
+			delete(q.live, oldest.Id)
+			q.dead++
+			if q.onOverflow != nil {
+				q.onOverflow(oldest)
+			}
+		case types.QueueDropNewest:
+			if q.onOverflow != nil {
+				q.onOverflow(msg)
+			}
+			return nil
+		case types.QueueBlock:
+			q.notFull.Wait()
+		}
+	}
+
+	q.nextId++
+	msg.Id = q.nextId
+
+	payload, err := encodePut(msg)
+	if err != nil {
+		return err
+	}
+	if err := q.writeRecordLocked(recordPut, payload); err != nil {
+		return err
+	}
+
+	q.order = append(q.order, msg.Id)
+	q.live[msg.Id] = msg
+
+	return nil
+}
+
+func (q *FileQueue) oldestLiveLocked() (*types.QueuedMessage, bool) {
+	for _, id := range q.order {
+		if m, ok := q.live[id]; ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+func (q *FileQueue) PeekBatch(n int) []*types.QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	batch := make([]*types.QueuedMessage, 0, len(q.live))
+	for _, id := range q.order {
+		if n > 0 && len(batch) >= n {
+			break
+		}
+		if m, ok := q.live[id]; ok {
+			batch = append(batch, m)
+		}
+	}
+	return batch
+}
+
+func (q *FileQueue) Ack(ids []uint64) {
+	if len(ids) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	toAck := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := q.live[id]; ok {
+			toAck = append(toAck, id)
+		}
+	}
+	if len(toAck) == 0 {
+		return
+	}
+
+	if err := q.writeRecordLocked(recordAck, encodeAck(toAck)); err != nil {
+		// The ack record failed to persist; leave the entries live so they
+		// are replayed (and re-delivered) rather than silently lost.
+		return
+	}
+	for _, id := range toAck {
+		delete(q.live, id)
+	}
+	q.dead += len(toAck)
+	q.notFull.Broadcast()
+
+	if q.dead >= deadRatioToCompact {
+		_ = q.compactLocked()
+	}
+}
+
+func (q *FileQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.live)
+}
+
+// replay reads the existing log (if any) and rebuilds q.order/q.live/q.nextId.
+// A truncated final record (e.g. left over from a crash mid-write) is
+// treated as the end of the log rather than an error.
+func (q *FileQueue) replay() error {
+	if _, err := q.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := &countingReader{r: q.file}
+	lastGood := int64(0)
+	for {
+		kind, payload, err := readRecord(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF || err == errCRCMismatch {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		lastGood = r.n
+
+		switch kind {
+		case recordPut:
+			id, msg, decodeErr := decodePut(payload)
+			if decodeErr != nil {
+				// A corrupt but fully-framed record (CRC matched, contents
+				// didn't decode) should not take down the whole queue.
+				continue
+			}
+			q.order = append(q.order, id)
+			q.live[id] = msg
+			if id > q.nextId {
+				q.nextId = id
+			}
+		case recordAck:
+			for _, id := range decodeAck(payload) {
+				if _, ok := q.live[id]; ok {
+					delete(q.live, id)
+					q.dead++
+				}
+			}
+		}
+	}
+
+	// Truncate any trailing, unreadable bytes so future appends start from a
+	// clean, known-good offset.
+	if err := q.file.Truncate(lastGood); err != nil {
+		return err
+	}
+	if _, err := q.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compactLocked rewrites the log to contain only currently-live messages,
+// dropping acked tombstones, then atomically swaps it in for the old file.
+// Callers must hold q.mu.
+func (q *FileQueue) compactLocked() error {
+	if q.dead == 0 {
+		return nil
+	}
+
+	tmpPath := q.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+
+	newOrder := make([]uint64, 0, len(q.live))
+	for _, id := range q.order {
+		msg, ok := q.live[id]
+		if !ok {
+			continue
+		}
+		payload, err := encodePut(msg)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		if err := writeRecord(tmp, recordPut, payload); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		newOrder = append(newOrder, id)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, q.path); err != nil {
+		return err
+	}
+
+	if err := q.file.Close(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+
+	q.file = f
+	q.order = newOrder
+	q.dead = 0
+	return nil
+}
+
+func (q *FileQueue) writeRecordLocked(kind byte, payload []byte) error {
+	if err := writeRecord(q.file, kind, payload); err != nil {
+		return err
+	}
+	return q.file.Sync()
+}
+
+// --- framing: [4-byte length][1-byte kind][payload][4-byte CRC32] ---
+
+var errCRCMismatch = fmt.Errorf("outbound queue: CRC mismatch")
+
+// maxRecordLen bounds how large a single record's length prefix is allowed to
+// claim to be. It protects replay against allocating gigabytes of memory for
+// a length field that was corrupted (e.g. by a crash mid-write).
+const maxRecordLen = 64 * 1024 * 1024
+
+func writeRecord(w io.Writer, kind byte, payload []byte) error {
+	body := make([]byte, 1+len(payload))
+	body[0] = kind
+	copy(body[1:], payload)
+
+	var header [4]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(len(body)))
+
+	crc := crc32.ChecksumIEEE(body)
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], crc)
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if _, err := w.Write(footer[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func readRecord(r *countingReader) (kind byte, payload []byte, err error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.LittleEndian.Uint32(header[:])
+	if length > maxRecordLen {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	var footer [4]byte
+	if _, err := io.ReadFull(r, footer[:]); err != nil {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(footer[:]) {
+		return 0, nil, errCRCMismatch
+	}
+
+	return body[0], body[1:], nil
+}
+
+// countingReader tracks how many bytes have been successfully consumed so
+// that replay can truncate a trailing, partially-written record.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func encodePut(msg *types.QueuedMessage) ([]byte, error) {
+	body, err := proto.Marshal(msg.Message)
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal queued message: %w", err)
+	}
+	out := make([]byte, 8+len(body))
+	binary.LittleEndian.PutUint64(out[:8], msg.Id)
+	copy(out[8:], body)
+	return out, nil
+}
+
+func decodePut(payload []byte) (uint64, *types.QueuedMessage, error) {
+	if len(payload) < 8 {
+		return 0, nil, fmt.Errorf("outbound queue: put record too short")
+	}
+	id := binary.LittleEndian.Uint64(payload[:8])
+
+	var envelope protobufs.AgentToServer
+	if err := proto.Unmarshal(payload[8:], &envelope); err != nil {
+		return 0, nil, err
+	}
+	return id, &types.QueuedMessage{Id: id, Message: &envelope}, nil
+}
+
+func encodeAck(ids []uint64) []byte {
+	out := make([]byte, 8*len(ids))
+	for i, id := range ids {
+		binary.LittleEndian.PutUint64(out[i*8:i*8+8], id)
+	}
+	return out
+}
+
+func decodeAck(payload []byte) []uint64 {
+	ids := make([]uint64, 0, len(payload)/8)
+	for i := 0; i+8 <= len(payload); i += 8 {
+		ids = append(ids, binary.LittleEndian.Uint64(payload[i:i+8]))
+	}
+	return ids
+}
@@ -0,0 +1,205 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+)
+
+func TestFileQueueReplayOrderingAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+	q.Ack([]uint64{2}) // acked out of order; should not be replayed
+
+	require.NoError(t, q.Close())
+
+	q2, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	defer q2.Close()
+
+	batch := q2.PeekBatch(0)
+	require.Len(t, batch, 2)
+	assert.Equal(t, []uint64{1, 3}, []uint64{batch[0].Id, batch[1].Id})
+}
+
+func TestFileQueueAckIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	defer q.Close()
+
+	require.NoError(t, q.Enqueue(msg(0)))
+	q.Ack([]uint64{1})
+	assert.Equal(t, 0, q.Len())
+
+	// Acking an id the Server has already observed (or that was already
+	// acked) a second time must be a safe no-op.
+	q.Ack([]uint64{1})
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestFileQueueOverflowDropOldest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	var dropped []*types.QueuedMessage
+	q, err := NewFileQueue(path, 1, types.QueueDropOldest, func(m *types.QueuedMessage) {
+		dropped = append(dropped, m)
+	}, time.Hour)
+	require.NoError(t, err)
+	defer q.Close()
+
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+
+	assert.Equal(t, 1, q.Len())
+	require.Len(t, dropped, 1)
+	assert.Equal(t, uint64(1), dropped[0].Id)
+}
+
+func TestFileQueueReconnectReplayIntegration(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+
+	// Simulate a drain loop that manages to write and Ack only the first
+	// two messages before the connection drops.
+	sent := drainAndAck(q, 2)
+	assert.Equal(t, []uint64{1, 2}, sent)
+	require.NoError(t, q.Close())
+
+	// Reconnect: a fresh FileQueue over the same path must replay exactly
+	// the un-acked tail, in order, so the drain loop can resend it over the
+	// new connection.
+	q2, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	defer q2.Close()
+
+	remaining := drainAndAck(q2, -1)
+	assert.Equal(t, []uint64{3}, remaining)
+	assert.Equal(t, 0, q2.Len())
+}
+
+func TestFileQueueAckBatchIsIdempotentAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+
+	// A single Ack call may cover several ids at once (e.g. the drain loop
+	// wrote and confirmed more than one message before the next Ack). Every
+	// id in the batch, not just the first, must be durably removed so a
+	// restart does not redeliver any of them.
+	q.Ack([]uint64{1, 2})
+	require.NoError(t, q.Close())
+
+	q2, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	defer q2.Close()
+
+	batch := q2.PeekBatch(0)
+	require.Len(t, batch, 1)
+	assert.Equal(t, uint64(3), batch[0].Id, "every acked id in the batch must be excluded from replay, not only the first")
+}
+
+func TestFileQueueBlockWaitsForSpace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 1, types.QueueBlock, nil, time.Hour)
+	require.NoError(t, err)
+	defer q.Close()
+
+	require.NoError(t, q.Enqueue(msg(0)))
+
+	enqueued := make(chan struct{})
+	go func() {
+		assert.NoError(t, q.Enqueue(msg(0)))
+		close(enqueued)
+	}()
+
+	select {
+	case <-enqueued:
+		t.Fatal("Enqueue returned before space was freed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	q.Ack([]uint64{1})
+
+	select {
+	case <-enqueued:
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue did not unblock after Ack freed space")
+	}
+}
+
+// drainAndAck simulates the sender's drain loop: it peeks at most limit
+// un-acked messages (all of them, if limit < 0), pretends to write each one
+// to the connection, Acks it, and returns the ids it sent, in order.
+func drainAndAck(q *FileQueue, limit int) []uint64 {
+	batch := q.PeekBatch(0)
+	if limit >= 0 && limit < len(batch) {
+		batch = batch[:limit]
+	}
+	var sent []uint64
+	for _, m := range batch {
+		sent = append(sent, m.Id)
+		q.Ack([]uint64{m.Id})
+	}
+	return sent
+}
+
+func TestFileQueueSurvivesPartialTrailingWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "queue.log")
+
+	q, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Enqueue(msg(0)))
+	require.NoError(t, q.Close())
+
+	// Simulate a crash mid-write by appending a truncated, bogus record
+	// after the last good one.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	q2, err := NewFileQueue(path, 0, types.QueueDropOldest, nil, time.Hour)
+	require.NoError(t, err)
+	defer q2.Close()
+
+	assert.Equal(t, 2, q2.Len())
+
+	// The queue must still be writable after recovering from the corrupt tail.
+	require.NoError(t, q2.Enqueue(msg(0)))
+	assert.Equal(t, 3, q2.Len())
+}
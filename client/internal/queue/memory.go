@@ -0,0 +1,106 @@
+// Package queue provides the bundled types.OutboundQueue implementations
+// used to buffer AgentToServer messages across reconnects.
+package queue
+
+import (
+	"sync"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+)
+
+// MemoryQueue is an in-memory, non-persistent types.OutboundQueue. It is the
+// default queue used when StartSettings.OutboundQueue is not set; messages
+// buffered in it do not survive a process restart.
+type MemoryQueue struct {
+	mu      sync.Mutex
+	notFull *sync.Cond
+
+	capacity   int
+	policy     types.QueueOverflowPolicy
+	onOverflow func(dropped *types.QueuedMessage)
+
+	nextId  uint64
+	entries []*types.QueuedMessage
+}
+
+// NewMemoryQueue creates an in-memory OutboundQueue. capacity <= 0 means
+// unbounded, in which case policy is never consulted. onOverflow, if
+// non-nil, is called synchronously from Enqueue whenever the overflow policy
+// causes a message to be dropped.
+func NewMemoryQueue(capacity int, policy types.QueueOverflowPolicy, onOverflow func(dropped *types.QueuedMessage)) *MemoryQueue {
+	q := &MemoryQueue{
+		capacity:   capacity,
+		policy:     policy,
+		onOverflow: onOverflow,
+	}
+	q.notFull = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *MemoryQueue) Enqueue(msg *types.QueuedMessage) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.capacity > 0 && len(q.entries) >= q.capacity {
+		switch q.policy {
+		case types.QueueDropOldest:
+			dropped := q.entries[0]
+			q.entries = q.entries[1:]
+			if q.onOverflow != nil {
+				q.onOverflow(dropped)
+			}
+		case types.QueueDropNewest:
+			if q.onOverflow != nil {
+				q.onOverflow(msg)
+			}
+			return nil
+		case types.QueueBlock:
+			q.notFull.Wait()
+		}
+	}
+
+	q.nextId++
+	msg.Id = q.nextId
+	q.entries = append(q.entries, msg)
+	return nil
+}
+
+func (q *MemoryQueue) PeekBatch(n int) []*types.QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n <= 0 || n > len(q.entries) {
+		n = len(q.entries)
+	}
+	batch := make([]*types.QueuedMessage, n)
+	copy(batch, q.entries[:n])
+	return batch
+}
+
+func (q *MemoryQueue) Ack(ids []uint64) {
+	if len(ids) == 0 {
+		return
+	}
+	acked := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		acked[id] = struct{}{}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	remaining := q.entries[:0]
+	for _, e := range q.entries {
+		if _, ok := acked[e.Id]; !ok {
+			remaining = append(remaining, e)
+		}
+	}
+	q.entries = remaining
+	q.notFull.Broadcast()
+}
+
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
@@ -0,0 +1,65 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+)
+
+func msg(id uint64) *types.QueuedMessage {
+	return &types.QueuedMessage{Id: id, Message: &protobufs.AgentToServer{}}
+}
+
+func TestMemoryQueueFIFOOrderingAndAck(t *testing.T) {
+	q := NewMemoryQueue(0, types.QueueDropOldest, nil)
+
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.Equal(t, 3, q.Len())
+
+	batch := q.PeekBatch(0)
+	assert.Len(t, batch, 3)
+	assert.Equal(t, []uint64{1, 2, 3}, []uint64{batch[0].Id, batch[1].Id, batch[2].Id})
+
+	q.Ack([]uint64{batch[0].Id})
+	assert.Equal(t, 2, q.Len())
+
+	remaining := q.PeekBatch(0)
+	assert.Equal(t, []uint64{2, 3}, []uint64{remaining[0].Id, remaining[1].Id})
+}
+
+func TestMemoryQueueDropOldest(t *testing.T) {
+	var dropped []*types.QueuedMessage
+	q := NewMemoryQueue(2, types.QueueDropOldest, func(m *types.QueuedMessage) {
+		dropped = append(dropped, m)
+	})
+
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.NoError(t, q.Enqueue(msg(0)))
+
+	assert.Equal(t, 2, q.Len())
+	assert.Len(t, dropped, 1)
+	assert.Equal(t, uint64(1), dropped[0].Id)
+
+	remaining := q.PeekBatch(0)
+	assert.Equal(t, []uint64{2, 3}, []uint64{remaining[0].Id, remaining[1].Id})
+}
+
+func TestMemoryQueueDropNewest(t *testing.T) {
+	var dropped []*types.QueuedMessage
+	q := NewMemoryQueue(1, types.QueueDropNewest, func(m *types.QueuedMessage) {
+		dropped = append(dropped, m)
+	})
+
+	assert.NoError(t, q.Enqueue(msg(0)))
+	assert.NoError(t, q.Enqueue(msg(0)))
+
+	assert.Equal(t, 1, q.Len())
+	assert.Len(t, dropped, 1)
+	assert.Equal(t, uint64(0), dropped[0].Id, "the dropped message never got assigned an id")
+}
@@ -3,7 +3,9 @@ package internal
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/open-telemetry/opamp-go/client/types"
@@ -11,6 +13,9 @@ import (
 	"github.com/open-telemetry/opamp-go/protobufs"
 )
 
+// pingWriteWait is the time allowed to write a ping or pong control frame.
+const pingWriteWait = 5 * time.Second
+
 // WSReceiver implements the WebSocket client's receiving portion of OpAMP protocol.
 type WSReceiver struct {
 	conn      *websocket.Conn
@@ -19,6 +24,17 @@ type WSReceiver struct {
 	callbacks types.Callbacks
 	processor receivedProcessor
 
+	// pingInterval and pongTimeout configure the transport-level keepalive.
+	// pingInterval == 0 disables the keepalive entirely.
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+
+	// pingMutex protects lastPingSentAt, the send time of the most recent
+	// ping, which the ping loop goroutine and the pong handler share for the
+	// round-trip latency measurement.
+	pingMutex      sync.Mutex
+	lastPingSentAt time.Time
+
 	// Indicates that the receiver has fully stopped.
 	stopped chan struct{}
 }
@@ -34,14 +50,23 @@ func NewWSReceiver(
 	packagesStateProvider types.PackagesStateProvider,
 	capabilities protobufs.AgentCapabilities,
 	packageSyncMutex *sync.Mutex,
+	pingInterval time.Duration,
+	pongTimeout time.Duration,
 ) *WSReceiver {
 	w := &WSReceiver{
-		conn:      conn,
-		logger:    logger,
-		sender:    sender,
-		callbacks: callbacks,
-		processor: newReceivedProcessor(logger, callbacks, sender, clientSyncedState, packagesStateProvider, capabilities, packageSyncMutex),
-		stopped:   make(chan struct{}),
+		conn:         conn,
+		logger:       logger,
+		sender:       sender,
+		callbacks:    callbacks,
+		processor:    newReceivedProcessor(logger, callbacks, sender, clientSyncedState, packagesStateProvider, capabilities, packageSyncMutex),
+		pingInterval: pingInterval,
+		pongTimeout:  pongTimeout,
+		stopped:      make(chan struct{}),
+	}
+
+	if w.pingInterval > 0 {
+		w.conn.SetPongHandler(w.onPong)
+		w.conn.SetPingHandler(w.onPing)
 	}
 
 	return w
@@ -49,6 +74,10 @@ func NewWSReceiver(
 
 // Start starts the receiver loop.
 func (r *WSReceiver) Start(ctx context.Context) {
+	if r.pingInterval > 0 {
+		_ = r.conn.SetReadDeadline(time.Now().Add(r.pingInterval + r.pongTimeout))
+		go r.pingLoop(ctx)
+	}
 	go r.ReceiverLoop(ctx)
 }
 
@@ -57,6 +86,69 @@ func (r *WSReceiver) IsStopped() <-chan struct{} {
 	return r.stopped
 }
 
+// pingLoop periodically sends a WebSocket ping control frame to the Server so
+// that a silently dropped connection (NAT timeout, half-open socket, etc.) is
+// detected without waiting for the OS TCP keepalive. To stop this goroutine
+// cancel ctx; closing the websocket connection alone does not stop it (each
+// failed ping write is logged and the loop keeps ticking).
+func (r *WSReceiver) pingLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.sendPing()
+		}
+	}
+}
+
+func (r *WSReceiver) sendPing() {
+	r.pingMutex.Lock()
+	r.lastPingSentAt = time.Now()
+	r.pingMutex.Unlock()
+
+	if err := r.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteWait)); err != nil {
+		r.logger.Debugf(context.Background(), "Failed to send ping: %v", err)
+	}
+}
+
+// onPong is installed as the WebSocket pong handler. It refreshes the read
+// deadline so that ReadMessage does not time out as long as pongs keep
+// arriving, and reports the observed round-trip latency via Callbacks.OnPong.
+func (r *WSReceiver) onPong(string) error {
+	now := time.Now()
+
+	r.pingMutex.Lock()
+	rtt := now.Sub(r.lastPingSentAt)
+	r.pingMutex.Unlock()
+
+	if err := r.conn.SetReadDeadline(now.Add(r.pingInterval + r.pongTimeout)); err != nil {
+		return err
+	}
+
+	if r.callbacks.OnPong != nil {
+		r.callbacks.OnPong(context.Background(), rtt)
+	}
+
+	return nil
+}
+
+// onPing is installed as the WebSocket ping handler so that a Server-initiated
+// ping is answered with a pong promptly, mirroring gorilla/websocket's default
+// ping handler but with our own write deadline.
+func (r *WSReceiver) onPing(appData string) error {
+	err := r.conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(pingWriteWait))
+	if err == websocket.ErrCloseSent {
+		return nil
+	} else if e, ok := err.(net.Error); ok && e.Timeout() {
+		return nil
+	}
+	return err
+}
+
 // ReceiverLoop runs the receiver loop.
 // To stop the receiver cancel the context and close the websocket connection
 func (r *WSReceiver) ReceiverLoop(ctx context.Context) {
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+)
+
+// dialWS starts a WebSocket echo-less test server and returns the client-side
+// and server-side connections. The caller is responsible for closing both.
+func dialWS(t *testing.T, upgradeHandler func(*websocket.Conn)) (client *websocket.Conn, closeSrv func()) {
+	t.Helper()
+
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		upgradeHandler(conn)
+	}))
+
+	wsURL := "ws" + srv.URL[len("http"):]
+	client, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("failed to dial test server: %v", err)
+	}
+
+	return client, srv.Close
+}
+
+func newTestWSReceiver(t *testing.T, conn *websocket.Conn, callbacks types.Callbacks, pingInterval, pongTimeout time.Duration) *WSReceiver {
+	r := &WSReceiver{
+		conn:         conn,
+		logger:       TestLogger{t},
+		callbacks:    callbacks,
+		pingInterval: pingInterval,
+		pongTimeout:  pongTimeout,
+		stopped:      make(chan struct{}),
+	}
+	if r.pingInterval > 0 {
+		r.conn.SetPongHandler(r.onPong)
+		r.conn.SetPingHandler(r.onPing)
+	}
+	return r
+}
+
+func TestWSReceiverPingPongNormal(t *testing.T) {
+	// gorilla/websocket's default server-side ping handler replies with a pong
+	// automatically, so the client should observe our OnPong callback fire.
+	client, closeSrv := dialWS(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer closeSrv()
+	defer client.Close()
+
+	pongCh := make(chan time.Duration, 1)
+	callbacks := types.Callbacks{
+		OnPong: func(ctx context.Context, rtt time.Duration) {
+			select {
+			case pongCh <- rtt:
+			default:
+			}
+		},
+	}
+
+	r := newTestWSReceiver(t, client, callbacks, 20*time.Millisecond, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A pong is only processed when the connection is actively being read
+	// from, so run the full receiver (ReceiverLoop + pingLoop) rather than
+	// just the ping loop in isolation.
+	r.Start(ctx)
+
+	select {
+	case rtt := <-pongCh:
+		assert.GreaterOrEqual(t, rtt, time.Duration(0))
+	case <-time.After(2 * time.Second):
+		t.Fatal("did not observe OnPong within the timeout")
+	}
+}
+
+func TestWSReceiverPongTimeoutTriggersReconnect(t *testing.T) {
+	// The test server never replies to pings, so the client's read deadline
+	// should expire and ReceiverLoop should return.
+	client, closeSrv := dialWS(t, func(conn *websocket.Conn) {
+		conn.SetPingHandler(func(string) error { return nil })
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer closeSrv()
+	defer client.Close()
+
+	r := newTestWSReceiver(t, client, types.Callbacks{}, 20*time.Millisecond, 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	select {
+	case <-r.IsStopped():
+		// expected: the read deadline fired and the receiver loop exited.
+	case <-time.After(2 * time.Second):
+		t.Fatal("receiver did not stop after pong timeout")
+	}
+}
+
+func TestWSReceiverServerInitiatedPing(t *testing.T) {
+	serverPonged := make(chan struct{}, 1)
+
+	client, closeSrv := dialWS(t, func(conn *websocket.Conn) {
+		conn.SetPongHandler(func(string) error {
+			select {
+			case serverPonged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		_ = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer closeSrv()
+	defer client.Close()
+
+	r := newTestWSReceiver(t, client, types.Callbacks{}, 20*time.Millisecond, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.ReceiverLoop(ctx)
+
+	select {
+	case <-serverPonged:
+		// expected: our onPing handler replied with a pong.
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not receive a pong in response to its ping")
+	}
+}
+
+func TestWSReceiverPingDisabled(t *testing.T) {
+	client, closeSrv := dialWS(t, func(conn *websocket.Conn) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	defer closeSrv()
+	defer client.Close()
+
+	r := newTestWSReceiver(t, client, types.Callbacks{}, 0, 0)
+	assert.Nil(t, r.conn.SetReadDeadline(time.Time{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.Start(ctx)
+
+	// No deadline should have been armed, so the receiver should stay up for
+	// longer than a typical keepalive interval would allow.
+	select {
+	case <-r.IsStopped():
+		t.Fatal("receiver stopped even though ping/pong keepalive is disabled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/internal"
+)
+
+// outboundQueueDrainInterval is how often the background drain loop started
+// by Start checks the attached outbound queue for messages enqueued since
+// the last drain (e.g. by SendCustomMessage while already connected).
+const outboundQueueDrainInterval = 200 * time.Millisecond
+
+// WSSender implements the WebSocket client's sending portion of the OpAMP
+// protocol. Its data message writes to conn are serialized by writeMu, since
+// gorilla/websocket allows at most one concurrent writer per connection.
+// Ping and pong control frames are written by WSReceiver with WriteControl,
+// which gorilla/websocket allows concurrently with other writes.
+type WSSender struct {
+	logger types.Logger
+
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	// outboundQueue and onAck are set by SetOutboundQueue. A message peeked
+	// from outboundQueue is Acked, and onAck invoked, only once the
+	// WriteMessage call for it returns success, so a message that fails to
+	// send is left in the queue to retry against the next connection. The
+	// exception is a message that cannot be encoded: it is Acked and dropped
+	// without being written, and onAck is not invoked for it.
+	outboundQueue types.OutboundQueue
+	onAck         func(id uint64)
+
+	stopOnce    sync.Once
+	stopped     chan struct{}
+	stoppingMu  sync.Mutex
+	stoppingErr error
+}
+
+// NewSender creates a new sender that uses WebSocket to send messages to
+// the Server.
+func NewSender(logger types.Logger) *WSSender {
+	return &WSSender{
+		logger:  logger,
+		stopped: make(chan struct{}),
+	}
+}
+
+// SetOutboundQueue attaches an outbound queue for DrainOutboundQueue and the
+// background drain loop started by Start to read from. Must be called
+// before Start.
+func (s *WSSender) SetOutboundQueue(queue types.OutboundQueue, onAck func(id uint64)) {
+	s.outboundQueue = queue
+	s.onAck = onAck
+}
+
+// DrainOutboundQueue writes every message currently buffered in the attached
+// outbound queue to conn, Acking each one (and invoking onAck) only once its
+// write succeeds; a message that cannot be encoded is Acked and dropped
+// instead. It stops at the first write failure, leaving that message and
+// everything enqueued after it for the next connection to retry.
+//
+// Callers are expected to call this once per connection, before
+// PrepareFirstMessage and before Start, so that a backlog accumulated while
+// disconnected is replayed ahead of new traffic on the new connection. It is
+// a no-op if no outbound queue is attached.
+func (s *WSSender) DrainOutboundQueue(ctx context.Context, conn *websocket.Conn) error {
+	if s.outboundQueue == nil {
+		return nil
+	}
+	s.conn = conn
+	return s.drainOnce(ctx)
+}
+
+// Start starts the sender against conn. If an outbound queue is attached via
+// SetOutboundQueue, it also starts a background loop that periodically
+// drains messages enqueued after the initial DrainOutboundQueue call.
+func (s *WSSender) Start(ctx context.Context, conn *websocket.Conn) error {
+	s.conn = conn
+
+	if s.outboundQueue != nil {
+		go s.drainLoop(ctx)
+	}
+
+	return nil
+}
+
+// IsStopped returns a channel that's closed once the sender has stopped,
+// e.g. because a queued message could not be written to the connection.
+func (s *WSSender) IsStopped() <-chan struct{} {
+	return s.stopped
+}
+
+// StoppingErr returns the error, if any, that caused the sender to stop.
+func (s *WSSender) StoppingErr() error {
+	s.stoppingMu.Lock()
+	defer s.stoppingMu.Unlock()
+	return s.stoppingErr
+}
+
+// drainLoop periodically drains the outbound queue until ctx is cancelled or
+// a write fails. A write failure means the connection is no longer usable,
+// so the loop stops and lets the caller reconnect and start a fresh sender.
+func (s *WSSender) drainLoop(ctx context.Context) {
+	ticker := time.NewTicker(outboundQueueDrainInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.stop(nil)
+			return
+		case <-ticker.C:
+			if err := s.drainOnce(ctx); err != nil {
+				s.logger.Debugf(ctx, "Outbound queue drain failed, will retry on the next connection: %v", err)
+				s.stop(err)
+				return
+			}
+		}
+	}
+}
+
+// drainOnce writes every currently un-acked queued message to s.conn, in
+// order, Acking each one only after its write succeeds. A message that
+// cannot be encoded is Acked and dropped without being written.
+func (s *WSSender) drainOnce(ctx context.Context) error {
+	for _, qmsg := range s.outboundQueue.PeekBatch(0) {
+		bytes, err := internal.EncodeWSMessage(qmsg.Message)
+		if err != nil {
+			// A message that can never be encoded would otherwise block the
+			// whole queue forever; drop it and move on.
+			s.logger.Errorf(ctx, "cannot encode queued message %d, dropping it: %v", qmsg.Id, err)
+			s.outboundQueue.Ack([]uint64{qmsg.Id})
+			continue
+		}
+
+		s.writeMu.Lock()
+		err = s.conn.WriteMessage(websocket.BinaryMessage, bytes)
+		s.writeMu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		s.outboundQueue.Ack([]uint64{qmsg.Id})
+		if s.onAck != nil {
+			s.onAck(qmsg.Id)
+		}
+	}
+	return nil
+}
+
+func (s *WSSender) stop(err error) {
+	s.stoppingMu.Lock()
+	if s.stoppingErr == nil {
+		s.stoppingErr = err
+	}
+	s.stoppingMu.Unlock()
+	s.stopOnce.Do(func() { close(s.stopped) })
+}
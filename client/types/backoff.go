@@ -0,0 +1,130 @@
+package types
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	sharedinternal "github.com/open-telemetry/opamp-go/internal"
+)
+
+// BackoffStop is returned by BackoffPolicy.NextInterval to signal that the
+// caller should give up reconnecting (e.g. MaxElapsedTime was exceeded)
+// instead of waiting and trying again.
+const BackoffStop time.Duration = -1
+
+// BackoffPolicy decides how long to wait between reconnect attempts. It lets
+// callers spread reconnect storms across a fleet (jitter), cap the maximum
+// wait, and observe retry decisions, instead of the client hardcoding an
+// unbounded exponential backoff.
+type BackoffPolicy interface {
+	// Reset clears any accumulated state (elapsed time, growth) so the
+	// policy starts fresh. Called once before the first connection attempt
+	// of a new attempt sequence.
+	Reset()
+
+	// NextInterval returns how long to wait before the next connection
+	// attempt. attempt is the 1-based count of consecutive failures so far
+	// in this attempt sequence. serverRetryAfter is populated when the
+	// Server returned a Retry-After on the attempt that just failed.
+	// Returning BackoffStop tells the caller to stop retrying.
+	NextInterval(attempt int, lastErr error, serverRetryAfter sharedinternal.OptionalDuration) time.Duration
+}
+
+// DefaultBackoffPolicy is a BackoffPolicy with an exponentially growing
+// interval and a configurable cap. NewDefaultBackoffPolicy returns one with
+// full-jitter randomization so that many clients reconnecting at once do not
+// retry in lockstep; the zero value applies no jitter.
+type DefaultBackoffPolicy struct {
+	// InitialInterval is the wait used for the first retry. Defaults to 5s.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the computed wait, before jitter is applied. Defaults to 30s.
+	MaxInterval time.Duration
+
+	// Multiplier grows the interval after each consecutive failure. Defaults to 1.5.
+	Multiplier float64
+
+	// RandomizationFactor controls how much of the computed interval is
+	// subject to jitter, from 0 (no jitter) to 1 (full jitter: the final
+	// wait is uniformly distributed between 0 and the computed interval).
+	// The zero value of DefaultBackoffPolicy means no jitter; use
+	// NewDefaultBackoffPolicy to get the recommended full-jitter default.
+	RandomizationFactor float64
+
+	// MaxElapsedTime bounds the total time spent retrying since the last
+	// Reset. Once exceeded, NextInterval returns BackoffStop. Zero (the
+	// default) means retry forever.
+	MaxElapsedTime time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	rnd     *rand.Rand
+}
+
+// NewDefaultBackoffPolicy returns a DefaultBackoffPolicy with the
+// recommended full-jitter RandomizationFactor; all other fields use their
+// defaults until overridden.
+func NewDefaultBackoffPolicy() *DefaultBackoffPolicy {
+	return &DefaultBackoffPolicy{RandomizationFactor: 1}
+}
+
+func (p *DefaultBackoffPolicy) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started = time.Time{}
+}
+
+func (p *DefaultBackoffPolicy) NextInterval(attempt int, lastErr error, serverRetryAfter sharedinternal.OptionalDuration) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.started.IsZero() {
+		p.started = time.Now()
+	}
+	if p.MaxElapsedTime > 0 && time.Since(p.started) > p.MaxElapsedTime {
+		return BackoffStop
+	}
+
+	initial := p.InitialInterval
+	if initial <= 0 {
+		initial = 5 * time.Second
+	}
+	max := p.MaxInterval
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1.5
+	}
+	randomizationFactor := p.RandomizationFactor
+
+	if attempt < 1 {
+		attempt = 1
+	}
+	capped := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if capped > float64(max) {
+		capped = float64(max)
+	}
+
+	interval := capped
+	if randomizationFactor > 0 {
+		if p.rnd == nil {
+			p.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		jittered := capped * randomizationFactor
+		base := capped - jittered
+		interval = base + p.rnd.Float64()*jittered
+	}
+
+	result := time.Duration(interval)
+	if serverRetryAfter.Defined && serverRetryAfter.Duration > result {
+		// Honour the Server's requested delay if it is longer than what we
+		// would otherwise wait.
+		result = serverRetryAfter.Duration
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sharedinternal "github.com/open-telemetry/opamp-go/internal"
+)
+
+func TestDefaultBackoffPolicyJitterDistribution(t *testing.T) {
+	p := NewDefaultBackoffPolicy()
+	p.InitialInterval = 100 * time.Millisecond
+	p.MaxInterval = time.Second
+	p.Multiplier = 2
+
+	sawSmall := false
+	sawLarge := false
+	for i := 0; i < 200; i++ {
+		d := p.NextInterval(3, nil, sharedinternal.OptionalDuration{})
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 400*time.Millisecond) // 100ms * 2^2; the 1s MaxInterval does not cap it
+		if d < 100*time.Millisecond {
+			sawSmall = true
+		}
+		if d > 300*time.Millisecond {
+			sawLarge = true
+		}
+	}
+	assert.True(t, sawSmall, "full jitter should sometimes produce short waits")
+	assert.True(t, sawLarge, "full jitter should sometimes produce waits close to the cap")
+}
+
+func TestDefaultBackoffPolicyNoJitterGrowsAndCaps(t *testing.T) {
+	p := &DefaultBackoffPolicy{
+		InitialInterval:     100 * time.Millisecond,
+		MaxInterval:         500 * time.Millisecond,
+		Multiplier:          2,
+		RandomizationFactor: 0,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, p.NextInterval(1, nil, sharedinternal.OptionalDuration{}))
+	assert.Equal(t, 200*time.Millisecond, p.NextInterval(2, nil, sharedinternal.OptionalDuration{}))
+	assert.Equal(t, 400*time.Millisecond, p.NextInterval(3, nil, sharedinternal.OptionalDuration{}))
+	// 100ms * 2^3 = 800ms, capped at 500ms.
+	assert.Equal(t, 500*time.Millisecond, p.NextInterval(4, nil, sharedinternal.OptionalDuration{}))
+}
+
+func TestDefaultBackoffPolicyHonoursRetryAfter(t *testing.T) {
+	p := &DefaultBackoffPolicy{
+		InitialInterval:     100 * time.Millisecond,
+		MaxInterval:         500 * time.Millisecond,
+		RandomizationFactor: 0,
+	}
+
+	d := p.NextInterval(1, nil, sharedinternal.OptionalDuration{Defined: true, Duration: 2 * time.Second})
+	assert.Equal(t, 2*time.Second, d, "a Server Retry-After longer than the computed interval must be honoured")
+
+	d = p.NextInterval(1, nil, sharedinternal.OptionalDuration{Defined: true, Duration: time.Millisecond})
+	assert.Equal(t, 100*time.Millisecond, d, "a Server Retry-After shorter than the computed interval must not shrink it")
+}
+
+func TestDefaultBackoffPolicyMaxElapsedTime(t *testing.T) {
+	p := &DefaultBackoffPolicy{
+		InitialInterval: time.Millisecond,
+		MaxElapsedTime:  10 * time.Millisecond,
+	}
+
+	err := errors.New("connect failed")
+	for i := 1; i <= 3; i++ {
+		d := p.NextInterval(i, err, sharedinternal.OptionalDuration{})
+		assert.NotEqual(t, BackoffStop, d)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, BackoffStop, p.NextInterval(4, err, sharedinternal.OptionalDuration{}))
+
+	p.Reset()
+	assert.NotEqual(t, BackoffStop, p.NextInterval(1, err, sharedinternal.OptionalDuration{}))
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	sharedinternal "github.com/open-telemetry/opamp-go/internal"
+)
+
+// Callbacks contains the callback functions that the OpAMP Client calls to
+// let the caller observe connection-level events. CheckRedirect, OnPong,
+// OnQueueOverflow and OnConnectRetry may be left nil; the WebSocket client
+// calls OnConnect and OnConnectFailed without checking for nil.
+type Callbacks struct {
+	// OnConnect is called when the connection is successfully established to the Server.
+	OnConnect func(ctx context.Context)
+
+	// OnConnectFailed is called when it was not possible to connect to the Server.
+	OnConnectFailed func(ctx context.Context, err error)
+
+	// CheckRedirect is called before following a redirect response from the
+	// Server, similar to http.Client.CheckRedirect. Returning an error aborts
+	// the redirect and fails the connection attempt. If nil, redirects are
+	// always followed.
+	CheckRedirect func(req *http.Request, via []*http.Request, responses []*http.Response) error
+
+	// OnPong is called whenever a transport-level pong is observed on the
+	// WebSocket connection, reporting the round-trip latency measured for the
+	// most recently sent ping. Only used by the WebSocket client when
+	// StartSettings.WSPingInterval is non-zero. If nil, this is a no-op.
+	OnPong func(ctx context.Context, rtt time.Duration)
+
+	// OnQueueOverflow is called when the outbound queue that the WebSocket
+	// client creates in Start (used when StartSettings.OutboundQueue is nil)
+	// drops a message under its overflow policy; dropped is the discarded
+	// message. That default queue is unbounded, so it never drops a message.
+	// OnQueueOverflow is not called for a caller-supplied
+	// StartSettings.OutboundQueue, nor when Enqueue waits under QueueBlock.
+	// If nil, this is a no-op.
+	OnQueueOverflow func(ctx context.Context, dropped *QueuedMessage)
+
+	// OnConnectRetry is called after a connection attempt fails, before the
+	// client sleeps for delay and retries. attempt is the 1-based count of
+	// consecutive failures in the current attempt sequence. Returning a
+	// non-nil error ends the current attempt sequence at once, without
+	// sleeping. It does not stop the client: unless Stop has been called, the
+	// client then starts a new attempt sequence right away (the BackoffPolicy
+	// is Reset and attempt counts from 1 again). If nil, this is a no-op.
+	OnConnectRetry func(ctx context.Context, attempt int, delay time.Duration, err error, retryAfter sharedinternal.OptionalDuration) error
+}
@@ -0,0 +1,53 @@
+package types
+
+import "github.com/open-telemetry/opamp-go/protobufs"
+
+// QueuedMessage is a single AgentToServer envelope buffered in an
+// OutboundQueue pending delivery to the Server. Id is assigned by the queue
+// when the message is enqueued and is used to Ack the message once it has
+// been successfully written to the connection.
+type QueuedMessage struct {
+	Id      uint64
+	Message *protobufs.AgentToServer
+}
+
+// QueueOverflowPolicy controls what an OutboundQueue does when Enqueue is
+// called while the queue is already at capacity.
+type QueueOverflowPolicy int
+
+const (
+	// QueueDropOldest discards the oldest buffered message to make room for
+	// the message being enqueued.
+	QueueDropOldest QueueOverflowPolicy = iota
+
+	// QueueDropNewest discards the message being enqueued, leaving the
+	// existing contents of the queue untouched.
+	QueueDropNewest
+
+	// QueueBlock blocks Enqueue until space becomes available, e.g. because
+	// older messages were Acked.
+	QueueBlock
+)
+
+// OutboundQueue buffers AgentToServer messages between ClientCommon and the
+// transport sender so that messages handed to the client survive a
+// connection drop and are replayed to the Server at-least-once after a
+// reconnect.
+type OutboundQueue interface {
+	// Enqueue buffers msg for delivery and assigns it an Id. It returns an
+	// error only if the queue is unable to accept the message at all (e.g. a
+	// closed persistent queue); applying the overflow policy is not an error.
+	Enqueue(msg *QueuedMessage) error
+
+	// PeekBatch returns up to n un-acked messages in the order they were
+	// enqueued, without removing them from the queue. n <= 0 means "all of
+	// them". Callers must Ack a message once it has been durably delivered.
+	PeekBatch(n int) []*QueuedMessage
+
+	// Ack removes the messages with the given ids from the queue. Ids that
+	// are not present (e.g. already acked) are ignored.
+	Ack(ids []uint64)
+
+	// Len returns the number of un-acked messages currently buffered.
+	Len() int
+}
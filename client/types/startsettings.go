@@ -46,4 +46,38 @@ type StartSettings struct {
 	//
 	// If the ReportsHeartbeat capability is disabled, this option has no effect.
 	HeartbeatInterval *time.Duration
+
+	// Optional WSPingInterval configures how often the WebSocket client sends a
+	// transport-level ping control frame to the Server. This is independent of
+	// HeartbeatInterval: it detects a dead connection (e.g. a silently dropped
+	// TCP socket) instead of driving application-level AgentToServer traffic.
+	// If zero, ping/pong keepalive is disabled. Only used by the WebSocket client.
+	WSPingInterval time.Duration
+
+	// Optional WSPongTimeout bounds how long the client will wait for a pong
+	// after sending a ping before treating the connection as dead and forcing
+	// a reconnect: the read deadline is set to WSPingInterval + WSPongTimeout
+	// from now when the receiver starts and again on each pong. Only pongs move
+	// the deadline; other messages received from the Server do not. Only used
+	// when WSPingInterval is non-zero. If zero, a default of 5 seconds is used.
+	WSPongTimeout time.Duration
+
+	// Optional OutboundQueue buffers the AgentToServer messages submitted via
+	// SendCustomMessage until the transport sender has written them, so that
+	// they survive a reconnect and are replayed to the Server at-least-once.
+	// Other AgentToServer messages do not go through this queue.
+	// If nil, an in-memory, non-persistent queue is
+	// used. See client/internal/queue for the bundled implementations.
+	OutboundQueue OutboundQueue
+
+	// Optional BackoffPolicy controls the delay between reconnect attempts.
+	// If nil, the client's previous hardcoded behavior (unbounded
+	// exponential backoff, honouring any Server Retry-After that exceeds the
+	// computed interval) is used. See DefaultBackoffPolicy for a tunable
+	// alternative with jitter and a maximum interval.
+	//
+	// Only wired into the WebSocket client's reconnect loop. There is
+	// currently no HTTP-based OpAMPClient implementation in this module to
+	// wire it into for symmetry; this field has no effect until one exists.
+	BackoffPolicy BackoffPolicy
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/open-telemetry/opamp-go/client/internal"
+	"github.com/open-telemetry/opamp-go/client/internal/queue"
 	"github.com/open-telemetry/opamp-go/client/types"
 	sharedinternal "github.com/open-telemetry/opamp-go/internal"
 	"github.com/open-telemetry/opamp-go/protobufs"
@@ -20,6 +21,10 @@ import (
 
 const (
 	defaultShutdownTimeout = 5 * time.Second
+
+	// defaultWSPongTimeout is used when StartSettings.WSPingInterval is set
+	// but StartSettings.WSPongTimeout is left at zero.
+	defaultWSPongTimeout = 5 * time.Second
 )
 
 // wsClient is an OpAMP Client implementation for WebSocket transport.
@@ -49,6 +54,34 @@ type wsClient struct {
 	// This field is currently only modified during testing.
 	connShutdownTimeout time.Duration
 
+	// wsPingInterval and wsPongTimeout configure the transport-level
+	// ping/pong keepalive on the WebSocket connection. wsPingInterval == 0
+	// disables the keepalive.
+	wsPingInterval time.Duration
+	wsPongTimeout  time.Duration
+
+	// backoffPolicy controls the delay between reconnect attempts in
+	// ensureConnected. Defaulted in Start() when StartSettings.BackoffPolicy
+	// is not set.
+	backoffPolicy types.BackoffPolicy
+
+	// outboundQueue buffers AgentToServer messages enqueued via
+	// SendCustomMessage so that they survive a reconnect and are retried
+	// at-least-once. Defaulted in Start() when StartSettings.OutboundQueue
+	// is not set. It is drained by primaryAgentId's sender: all of this
+	// client's senders share the same underlying connection, so only one of
+	// them may safely write to it.
+	outboundQueue  types.OutboundQueue
+	primaryAgentId types.InstanceUid
+
+	// ackWaiters tracks the channel returned by SendCustomMessage for each
+	// not-yet-delivered queued message, keyed by its outboundQueue id. It is
+	// closed (and removed) once the message is Acked, or once the default
+	// queue created in Start() drops it under its overflow policy; a drop by
+	// a caller-supplied StartSettings.OutboundQueue does not close it.
+	ackWaitersMu sync.Mutex
+	ackWaiters   map[uint64]chan struct{}
+
 	// responseChain is used for the "via" argument in CheckRedirect.
 	// It is appended to with every redirect followed, and zeroed on a succesful
 	// connection. responseChain should only be referred to by the goroutine that
@@ -65,18 +98,22 @@ func NewWebSocket(logger types.Logger) *wsClient {
 	w := &wsClient{
 		common:              internal.NewClientCommon(logger),
 		connShutdownTimeout: defaultShutdownTimeout,
+		ackWaiters:          map[uint64]chan struct{}{},
 	}
 	return w
 }
 
 func (c *wsClient) PrepareStart(ctx context.Context, settings types.StartSettings) error {
-	for _, agent := range settings.Agents {
+	for i, agent := range settings.Agents {
 		// sender is shared between this client and common client
 		sender := internal.NewSender(c.common.Logger)
 		c.senders[agent.InstanceUid] = sender
 		c.common.Agents[agent.InstanceUid].Sender = sender
 		c.common.SetAgentDescription(agent.InstanceUid, agent.AgentDescription)
 		c.common.SetHealth(agent.InstanceUid, &protobufs.ComponentHealth{Healthy: false})
+		if i == 0 {
+			c.primaryAgentId = agent.InstanceUid
+		}
 	}
 
 	return c.common.PrepareStart(ctx, settings)
@@ -115,6 +152,22 @@ func (c *wsClient) Start(ctx context.Context, settings types.StartSettings) erro
 		return headerFunc(baseHeader.Clone())
 	}
 
+	c.wsPingInterval = settings.WSPingInterval
+	c.wsPongTimeout = settings.WSPongTimeout
+	if c.wsPingInterval > 0 && c.wsPongTimeout <= 0 {
+		c.wsPongTimeout = defaultWSPongTimeout
+	}
+
+	c.backoffPolicy = settings.BackoffPolicy
+	if c.backoffPolicy == nil {
+		c.backoffPolicy = newLegacyBackoffPolicy()
+	}
+
+	c.outboundQueue = settings.OutboundQueue
+	if c.outboundQueue == nil {
+		c.outboundQueue = queue.NewMemoryQueue(0, types.QueueDropOldest, c.onQueueOverflow)
+	}
+
 	c.common.StartConnectAndRun(c.runUntilStopped)
 
 	return nil
@@ -160,8 +213,64 @@ func (c *wsClient) SetFlags(agentId types.InstanceUid, flags protobufs.AgentToSe
 	c.common.SetFlags(agentId, flags)
 }
 
+// SendCustomMessage buffers message in c.outboundQueue instead of sending it
+// directly, so that it survives a reconnect. The returned channel is closed
+// once the message has been durably handed to the connection (Acked by the
+// queue), not merely enqueued.
 func (c *wsClient) SendCustomMessage(agentId types.InstanceUid, message *protobufs.CustomMessage) (messageSendingChannel chan struct{}, err error) {
-	return c.common.SendCustomMessage(agentId, message)
+	qmsg := &types.QueuedMessage{
+		Message: &protobufs.AgentToServer{
+			InstanceUid:   agentId[:],
+			CustomMessage: message,
+		},
+	}
+
+	if err := c.outboundQueue.Enqueue(qmsg); err != nil {
+		return nil, err
+	}
+
+	ackCh := make(chan struct{})
+	c.ackWaitersMu.Lock()
+	c.ackWaiters[qmsg.Id] = ackCh
+	c.ackWaitersMu.Unlock()
+
+	return ackCh, nil
+}
+
+// onQueueOverflow adapts the onOverflow callback used by the bundled queue
+// implementations (which, like OutboundQueue.Enqueue itself, takes no
+// context) to Callbacks.OnQueueOverflow. It is only wired up for the default
+// queue created in Start(); a caller-supplied StartSettings.OutboundQueue is
+// responsible for its own overflow callback.
+func (c *wsClient) onQueueOverflow(dropped *types.QueuedMessage) {
+	c.ackWaitersMu.Lock()
+	ch, ok := c.ackWaiters[dropped.Id]
+	if ok {
+		delete(c.ackWaiters, dropped.Id)
+	}
+	c.ackWaitersMu.Unlock()
+	if ok {
+		close(ch)
+	}
+
+	if c.common.Callbacks.OnQueueOverflow != nil {
+		c.common.Callbacks.OnQueueOverflow(context.Background(), dropped)
+	}
+}
+
+// onQueueAcked is called once a queued message has been written to the
+// connection and Acked, so that SendCustomMessage's returned channel can be
+// closed to signal durable delivery.
+func (c *wsClient) onQueueAcked(id uint64) {
+	c.ackWaitersMu.Lock()
+	ch, ok := c.ackWaiters[id]
+	if ok {
+		delete(c.ackWaiters, id)
+	}
+	c.ackWaitersMu.Unlock()
+	if ok {
+		close(ch)
+	}
 }
 
 // SetAvailableComponents implements OpAMPClient.SetAvailableComponents
@@ -269,24 +378,51 @@ func (c *wsClient) tryConnectOnce(ctx context.Context) (retryAfter sharedinterna
 	return sharedinternal.OptionalDuration{Defined: false}, nil
 }
 
+// legacyBackoffPolicy reproduces the client's reconnect behavior from before
+// StartSettings.BackoffPolicy existed: an unbounded exponential backoff that
+// honours a Server Retry-After when it is longer than the computed interval.
+// It is the default used when StartSettings.BackoffPolicy is nil.
+type legacyBackoffPolicy struct {
+	b *backoff.ExponentialBackOff
+}
+
+func newLegacyBackoffPolicy() *legacyBackoffPolicy {
+	b := backoff.NewExponentialBackOff()
+	// Make it retry forever.
+	b.MaxElapsedTime = 0
+	return &legacyBackoffPolicy{b: b}
+}
+
+func (p *legacyBackoffPolicy) Reset() {
+	p.b.Reset()
+}
+
+func (p *legacyBackoffPolicy) NextInterval(attempt int, lastErr error, serverRetryAfter sharedinternal.OptionalDuration) time.Duration {
+	interval := p.b.NextBackOff()
+	if serverRetryAfter.Defined && serverRetryAfter.Duration > interval {
+		interval = serverRetryAfter.Duration
+	}
+	return interval
+}
+
 // Continuously try until connected. Will return nil when successfully
-// connected. Will return error if it is cancelled via context.
+// connected. Will return error if it is cancelled via context or if
+// c.backoffPolicy gives up (types.BackoffStop).
 func (c *wsClient) ensureConnected(ctx context.Context) error {
-	infiniteBackoff := backoff.NewExponentialBackOff()
-
-	// Make ticker run forever.
-	infiniteBackoff.MaxElapsedTime = 0
+	c.backoffPolicy.Reset()
 
 	interval := time.Duration(0)
+	attempt := 0
 
 	for {
 		timer := time.NewTimer(interval)
-		interval = infiniteBackoff.NextBackOff()
 
 		select {
 		case <-timer.C:
 			{
-				if retryAfter, err := c.tryConnectOnce(ctx); err != nil {
+				retryAfter, err := c.tryConnectOnce(ctx)
+				if err != nil {
+					attempt++
 					c.lastInternalErr.Store(&err)
 					if errors.Is(err, context.Canceled) {
 						c.common.Logger.Debugf(ctx, "Client is stopped, will not try anymore.")
@@ -294,13 +430,23 @@ func (c *wsClient) ensureConnected(ctx context.Context) error {
 					} else {
 						c.common.Logger.Errorf(ctx, "Connection failed (%v), will retry.", err)
 					}
-					// Retry again a bit later.
 
-					if retryAfter.Defined && retryAfter.Duration > interval {
-						// If the Server suggested connecting later than our interval
-						// then honour Server's request, otherwise wait at least
-						// as much as we calculated.
-						interval = retryAfter.Duration
+					// Ask the policy how long to wait before the next attempt,
+					// honouring any Server-requested Retry-After internally.
+					interval = c.backoffPolicy.NextInterval(attempt, err, retryAfter)
+
+					if interval < 0 {
+						// The policy has given up; there is no delay to report,
+						// so OnConnectRetry (which fires "before the client
+						// sleeps for delay") does not apply here.
+						c.common.Logger.Errorf(ctx, "Giving up reconnecting after %d attempts.", attempt)
+						return err
+					}
+
+					if c.common.Callbacks.OnConnectRetry != nil {
+						if abortErr := c.common.Callbacks.OnConnectRetry(ctx, attempt, interval, err, retryAfter); abortErr != nil {
+							return abortErr
+						}
 					}
 
 					continue
@@ -342,6 +488,19 @@ func (c *wsClient) runOneCycle(ctx context.Context) {
 		return
 	}
 
+	// Hand the outbound queue to exactly one sender: every sender in
+	// c.senders writes to the same c.conn, so only one of them may safely
+	// drain it. Drain it now, before PrepareFirstMessage, so that anything
+	// still un-acked from a previous connection is replayed ahead of new
+	// traffic.
+	if primary, ok := c.senders[c.primaryAgentId]; ok {
+		primary.SetOutboundQueue(c.outboundQueue, c.onQueueAcked)
+		if err := primary.DrainOutboundQueue(ctx, c.conn); err != nil {
+			c.common.Logger.Errorf(ctx, "Failed to drain outbound queue: %v", err)
+			return
+		}
+	}
+
 	// Prepare the first status report.
 	err := c.common.PrepareFirstMessage(ctx)
 	if err != nil {
@@ -380,6 +539,8 @@ func (c *wsClient) runOneCycle(ctx context.Context) {
 			c.common.Agents[id].PackagesStateProvider,
 			c.common.Agents[id].Capabilities,
 			&c.common.PackageSyncMutex,
+			c.wsPingInterval,
+			c.wsPongTimeout,
 		)
 		r.Start(receiverCtx)
 		go func() {
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	sharedinternal "github.com/open-telemetry/opamp-go/internal"
+)
+
+// closedPortURL returns a ws:// URL for a TCP port that is guaranteed to
+// refuse connections, so dialing it fails immediately without needing a real
+// WebSocket handshake.
+func closedPortURL(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return "ws://" + addr
+}
+
+// TestEnsureConnectedAbortsOnConnectRetryError verifies that ensureConnected
+// stops retrying and returns the error produced by Callbacks.OnConnectRetry,
+// instead of sleeping for the computed interval and retrying again.
+func TestEnsureConnectedAbortsOnConnectRetryError(t *testing.T) {
+	c := NewWebSocket(nil)
+
+	u, err := url.Parse(closedPortURL(t))
+	require.NoError(t, err)
+	c.url = u
+	c.dialer = *websocket.DefaultDialer
+	c.getHeader = func() http.Header { return http.Header{} }
+	c.backoffPolicy = &types.DefaultBackoffPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+	}
+
+	abortErr := errors.New("caller aborted retrying")
+	retryCalls := 0
+	c.common.Callbacks = types.Callbacks{
+		OnConnectFailed: func(ctx context.Context, err error) {},
+		OnConnectRetry: func(ctx context.Context, attempt int, delay time.Duration, err error, retryAfter sharedinternal.OptionalDuration) error {
+			retryCalls++
+			return abortErr
+		},
+	}
+
+	err = c.ensureConnected(context.Background())
+	require.ErrorIs(t, err, abortErr)
+	require.Equal(t, 1, retryCalls, "ensureConnected must stop at the first aborted retry instead of retrying again")
+}